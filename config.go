@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+var configFile string
+
+// Config describes operator-tunable collection behavior loaded from
+// --config.file. It lets a fleet suppress specific metrics or devices,
+// attach extra identifying labels, and standardize the units memory is
+// reported in across heterogeneous hosts.
+type Config struct {
+	ExcludeMetrics []string `yaml:"exclude_metrics" json:"exclude_metrics"`
+	ExcludeDevices []string `yaml:"exclude_devices" json:"exclude_devices"`
+
+	AddPCIInfoTag      bool `yaml:"add_pci_info_tag" json:"add_pci_info_tag"`
+	AddUUIDMeta        bool `yaml:"add_uuid_meta" json:"add_uuid_meta"`
+	AddBoardNumberMeta bool `yaml:"add_board_number_meta" json:"add_board_number_meta"`
+	AddSerialMeta      bool `yaml:"add_serial_meta" json:"add_serial_meta"`
+
+	UnitPrefix map[string]string `yaml:"unit_prefix" json:"unit_prefix"`
+
+	// Targets maps a /probe?target= hostname to the SSH credentials used to
+	// reach it for RemoteSmiSource.
+	Targets map[string]RemoteTarget `yaml:"targets" json:"targets"`
+}
+
+// loadConfig reads and parses the collector config at path. An empty path
+// returns the zero-value Config: no metrics or devices excluded, no extra
+// metadata labels, memory reported in bytes.
+func loadConfig(path string) (*Config, error) {
+	cfg := &Config{}
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %s", path, err.Error())
+	}
+
+	if strings.HasSuffix(path, ".json") {
+		err = json.Unmarshal(data, cfg)
+	} else {
+		err = yaml.Unmarshal(data, cfg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %s", path, err.Error())
+	}
+	return cfg, nil
+}
+
+// excludesMetric reports whether name was listed under exclude_metrics.
+func (c *Config) excludesMetric(name string) bool {
+	for _, excluded := range c.ExcludeMetrics {
+		if excluded == name {
+			return true
+		}
+	}
+	return false
+}
+
+// excludesDevice reports whether the device with the given UUID was listed
+// under exclude_devices.
+func (c *Config) excludesDevice(uuid string) bool {
+	for _, excluded := range c.ExcludeDevices {
+		if excluded == uuid {
+			return true
+		}
+	}
+	return false
+}
+
+// memoryUnit returns the unit name and the byte divisor to use when
+// reporting memory metrics, as configured by unit_prefix["memory"]. It
+// defaults to plain bytes.
+func (c *Config) memoryUnit() (unit string, divisor float64) {
+	switch strings.ToUpper(c.UnitPrefix["memory"]) {
+	case "KIB":
+		return "KiB", 1024
+	case "MIB":
+		return "MiB", 1024 * 1024
+	case "GIB":
+		return "GiB", 1024 * 1024 * 1024
+	default:
+		return "bytes", 1
+	}
+}
+
+// temperatureUnit returns the unit name and the conversion function to use
+// when reporting the configurable temperature metric, as configured by
+// unit_prefix["temperature"]. It defaults to Celsius. convert takes the raw
+// GPU die temperature in Celsius, as returned by NVML.
+func (c *Config) temperatureUnit() (unit string, convert func(celsius int) float64) {
+	switch strings.ToUpper(c.UnitPrefix["temperature"]) {
+	case "K":
+		return "Kelvin", func(celsius int) float64 { return float64(celsius) + 273.15 }
+	case "F":
+		return "Fahrenheit", func(celsius int) float64 { return float64(celsius)*9/5 + 32 }
+	default:
+		return "Celsius", func(celsius int) float64 { return float64(celsius) }
+	}
+}
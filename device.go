@@ -0,0 +1,402 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+)
+
+// NVMLMemory holds a GPU's memory usage, reported in bytes.
+type NVMLMemory struct {
+	Total uint64
+	Used  uint64
+	Free  uint64
+}
+
+// Device wraps an nvml.Device handle together with the metadata used to
+// label every metric scraped for it.
+type Device struct {
+	i int
+
+	DeviceUUID string
+	DeviceName string
+
+	handle nvml.Device
+}
+
+// InitNVML initializes the NVML library. It must be called before any other
+// NVML call and paired with a deferred call to ShutdownNVML.
+func InitNVML() error {
+	return nvmlErr(nvml.Init())
+}
+
+// ShutdownNVML releases the resources held by NVML.
+func ShutdownNVML() error {
+	return nvmlErr(nvml.Shutdown())
+}
+
+// nvmlErr converts an nvml.Return code into a Go error, returning nil on success.
+func nvmlErr(ret nvml.Return) error {
+	if ret == nvml.SUCCESS {
+		return nil
+	}
+	return fmt.Errorf(nvml.ErrorString(ret))
+}
+
+// isNotSupported reports whether err came from an NVML call that returned
+// ERROR_NOT_SUPPORTED, e.g. a sensor or feature missing on non-datacenter
+// GPUs. Callers collecting an optional metric should skip it rather than
+// fail the whole scrape when this is true.
+func isNotSupported(err error) bool {
+	return err != nil && err.Error() == nvml.ErrorString(nvml.ERROR_NOT_SUPPORTED)
+}
+
+// GetDevices enumerates every NVIDIA GPU visible to NVML on this machine.
+func GetDevices() ([]Device, error) {
+	count, ret := nvml.DeviceGetCount()
+	if err := nvmlErr(ret); err != nil {
+		return nil, fmt.Errorf("failed to get device count: %s", err.Error())
+	}
+
+	devices := make([]Device, 0, count)
+	for i := 0; i < count; i++ {
+		handle, ret := nvml.DeviceGetHandleByIndex(i)
+		if err := nvmlErr(ret); err != nil {
+			return nil, fmt.Errorf("failed to get handle for device %d: %s", i, err.Error())
+		}
+
+		uuid, ret := handle.GetUUID()
+		if err := nvmlErr(ret); err != nil {
+			return nil, fmt.Errorf("failed to get UUID for device %d: %s", i, err.Error())
+		}
+
+		name, ret := handle.GetName()
+		if err := nvmlErr(ret); err != nil {
+			return nil, fmt.Errorf("failed to get name for device %d: %s", i, err.Error())
+		}
+
+		devices = append(devices, Device{
+			i:          i,
+			DeviceUUID: uuid,
+			DeviceName: name,
+			handle:     handle,
+		})
+	}
+
+	return devices, nil
+}
+
+// GetUtilization returns the percent of the GPU and its memory bandwidth that are utilized.
+func (d *Device) GetUtilization() (gpuPercent, memoryPercent int, err error) {
+	util, ret := d.handle.GetUtilizationRates()
+	if err = nvmlErr(ret); err != nil {
+		return 0, 0, err
+	}
+	return int(util.Gpu), int(util.Memory), nil
+}
+
+// GetTemperature returns the GPU die temperature in both Fahrenheit and Celsius.
+func (d *Device) GetTemperature() (tempF, tempC int, err error) {
+	c, ret := d.handle.GetTemperature(nvml.TEMPERATURE_GPU)
+	if err = nvmlErr(ret); err != nil {
+		return 0, 0, err
+	}
+	tempC = int(c)
+	tempF = tempC*9/5 + 32
+	return tempF, tempC, nil
+}
+
+// GetPowerUsage returns the GPU's current power draw in Watts.
+func (d *Device) GetPowerUsage() (int, error) {
+	milliwatts, ret := d.handle.GetPowerUsage()
+	if err := nvmlErr(ret); err != nil {
+		return 0, err
+	}
+	return int(milliwatts / 1000), nil
+}
+
+// GetMemoryInfo returns the GPU's total, used and free memory in bytes.
+func (d *Device) GetMemoryInfo() (NVMLMemory, error) {
+	mem, ret := d.handle.GetMemoryInfo()
+	if err := nvmlErr(ret); err != nil {
+		return NVMLMemory{}, err
+	}
+	return NVMLMemory{
+		Total: mem.Total,
+		Used:  mem.Used,
+		Free:  mem.Free,
+	}, nil
+}
+
+// NVMLClocks holds the operating frequency, in MHz, of each clock domain on the GPU.
+type NVMLClocks struct {
+	SM       int
+	Memory   int
+	Graphics int
+	Video    int
+}
+
+// GetClocks returns the current frequency of every clock domain on the GPU, in MHz.
+func (d *Device) GetClocks() (clocks NVMLClocks, err error) {
+	sm, ret := d.handle.GetClockInfo(nvml.CLOCK_SM)
+	if err = nvmlErr(ret); err != nil {
+		return
+	}
+	mem, ret := d.handle.GetClockInfo(nvml.CLOCK_MEM)
+	if err = nvmlErr(ret); err != nil {
+		return
+	}
+	graphics, ret := d.handle.GetClockInfo(nvml.CLOCK_GRAPHICS)
+	if err = nvmlErr(ret); err != nil {
+		return
+	}
+	video, ret := d.handle.GetClockInfo(nvml.CLOCK_VIDEO)
+	if err = nvmlErr(ret); err != nil {
+		return
+	}
+	clocks = NVMLClocks{SM: int(sm), Memory: int(mem), Graphics: int(graphics), Video: int(video)}
+	return
+}
+
+// GetClockThrottleReasons returns the bitmask of reasons the GPU's clocks are currently throttled.
+func (d *Device) GetClockThrottleReasons() (uint64, error) {
+	reasons, ret := d.handle.GetCurrentClocksThrottleReasons()
+	if err := nvmlErr(ret); err != nil {
+		return 0, err
+	}
+	return reasons, nil
+}
+
+// NVMLEccErrors holds single and double bit ECC error counts, both volatile
+// (since last driver reload) and aggregate (lifetime).
+type NVMLEccErrors struct {
+	VolatileSingleBit  uint64
+	VolatileDoubleBit  uint64
+	AggregateSingleBit uint64
+	AggregateDoubleBit uint64
+}
+
+// GetEccErrors returns the GPU's single and double bit ECC error counts.
+func (d *Device) GetEccErrors() (ecc NVMLEccErrors, err error) {
+	volSingle, ret := d.handle.GetTotalEccErrors(nvml.MEMORY_ERROR_TYPE_CORRECTED, nvml.VOLATILE_ECC)
+	if err = nvmlErr(ret); err != nil {
+		return
+	}
+	volDouble, ret := d.handle.GetTotalEccErrors(nvml.MEMORY_ERROR_TYPE_UNCORRECTED, nvml.VOLATILE_ECC)
+	if err = nvmlErr(ret); err != nil {
+		return
+	}
+	aggSingle, ret := d.handle.GetTotalEccErrors(nvml.MEMORY_ERROR_TYPE_CORRECTED, nvml.AGGREGATE_ECC)
+	if err = nvmlErr(ret); err != nil {
+		return
+	}
+	aggDouble, ret := d.handle.GetTotalEccErrors(nvml.MEMORY_ERROR_TYPE_UNCORRECTED, nvml.AGGREGATE_ECC)
+	if err = nvmlErr(ret); err != nil {
+		return
+	}
+	ecc = NVMLEccErrors{
+		VolatileSingleBit:  volSingle,
+		VolatileDoubleBit:  volDouble,
+		AggregateSingleBit: aggSingle,
+		AggregateDoubleBit: aggDouble,
+	}
+	return
+}
+
+// NVMLPcie holds PCIe throughput, in bytes per second, and link parameters for a GPU.
+type NVMLPcie struct {
+	TxBytes   uint64
+	RxBytes   uint64
+	LinkGen   int
+	LinkWidth int
+}
+
+// GetPcieInfo returns the GPU's current PCIe tx/rx throughput and link generation/width.
+func (d *Device) GetPcieInfo() (pcie NVMLPcie, err error) {
+	tx, ret := d.handle.GetPcieThroughput(nvml.PCIE_UTIL_TX_BYTES)
+	if err = nvmlErr(ret); err != nil {
+		return
+	}
+	rx, ret := d.handle.GetPcieThroughput(nvml.PCIE_UTIL_RX_BYTES)
+	if err = nvmlErr(ret); err != nil {
+		return
+	}
+	gen, ret := d.handle.GetCurrPcieLinkGeneration()
+	if err = nvmlErr(ret); err != nil {
+		return
+	}
+	width, ret := d.handle.GetCurrPcieLinkWidth()
+	if err = nvmlErr(ret); err != nil {
+		return
+	}
+	// NVML reports PCIe throughput in KB/s.
+	pcie = NVMLPcie{TxBytes: uint64(tx) * 1024, RxBytes: uint64(rx) * 1024, LinkGen: gen, LinkWidth: width}
+	return
+}
+
+// GetFanSpeed returns the GPU fan speed as a percent of its maximum.
+func (d *Device) GetFanSpeed() (int, error) {
+	speed, ret := d.handle.GetFanSpeed()
+	if err := nvmlErr(ret); err != nil {
+		return 0, err
+	}
+	return int(speed), nil
+}
+
+// GetPerformanceState returns the GPU's current performance state (P-state), 0 being the highest.
+func (d *Device) GetPerformanceState() (int, error) {
+	pstate, ret := d.handle.GetPerformanceState()
+	if err := nvmlErr(ret); err != nil {
+		return 0, err
+	}
+	return int(pstate), nil
+}
+
+// NVMLPowerLimits holds the GPU's current and default power management limits, in Watts.
+type NVMLPowerLimits struct {
+	Limit        int
+	DefaultLimit int
+}
+
+// GetPowerLimits returns the GPU's configured and default power management limits.
+func (d *Device) GetPowerLimits() (limits NVMLPowerLimits, err error) {
+	limit, ret := d.handle.GetPowerManagementLimit()
+	if err = nvmlErr(ret); err != nil {
+		return
+	}
+	defaultLimit, ret := d.handle.GetPowerManagementDefaultLimit()
+	if err = nvmlErr(ret); err != nil {
+		return
+	}
+	limits = NVMLPowerLimits{Limit: int(limit / 1000), DefaultLimit: int(defaultLimit / 1000)}
+	return
+}
+
+// GetEncoderUtilization returns the percent of the GPU's video encoder that is in use.
+func (d *Device) GetEncoderUtilization() (int, error) {
+	util, _, ret := d.handle.GetEncoderUtilization()
+	if err := nvmlErr(ret); err != nil {
+		return 0, err
+	}
+	return int(util), nil
+}
+
+// GetDecoderUtilization returns the percent of the GPU's video decoder that is in use.
+func (d *Device) GetDecoderUtilization() (int, error) {
+	util, _, ret := d.handle.GetDecoderUtilization()
+	if err := nvmlErr(ret); err != nil {
+		return 0, err
+	}
+	return int(util), nil
+}
+
+// NVMLProcess describes a single process currently using the GPU.
+type NVMLProcess struct {
+	PID           int
+	UsedGPUMemory uint64
+}
+
+// GetTotalEnergyConsumption returns the total energy consumed by the GPU since
+// the driver was last loaded, in Joules.
+func (d *Device) GetTotalEnergyConsumption() (uint64, error) {
+	millijoules, ret := d.handle.GetTotalEnergyConsumption()
+	if err := nvmlErr(ret); err != nil {
+		return 0, err
+	}
+	return millijoules / 1000, nil
+}
+
+// GetPcieReplayCounter returns the total number of PCIe replay events for the GPU.
+func (d *Device) GetPcieReplayCounter() (uint64, error) {
+	count, ret := d.handle.GetPcieReplayCounter()
+	if err := nvmlErr(ret); err != nil {
+		return 0, err
+	}
+	return uint64(count), nil
+}
+
+// GetRetiredPagesCounts returns the number of memory pages retired due to
+// single and double bit ECC errors respectively.
+func (d *Device) GetRetiredPagesCounts() (singleBit, doubleBit uint64, err error) {
+	singleBitPages, ret := d.handle.GetRetiredPages(nvml.PAGE_RETIREMENT_CAUSE_MULTIPLE_SINGLE_BIT_ECC_ERRORS)
+	if err = nvmlErr(ret); err != nil {
+		return
+	}
+	doubleBitPages, ret := d.handle.GetRetiredPages(nvml.PAGE_RETIREMENT_CAUSE_DOUBLE_BIT_ECC_ERROR)
+	if err = nvmlErr(ret); err != nil {
+		return
+	}
+	return uint64(len(singleBitPages)), uint64(len(doubleBitPages)), nil
+}
+
+// GetPciBusID returns the GPU's PCI bus ID, e.g. "00000000:01:00.0".
+func (d *Device) GetPciBusID() (string, error) {
+	info, ret := d.handle.GetPciInfo()
+	if err := nvmlErr(ret); err != nil {
+		return "", err
+	}
+	return int8SliceToString(info.BusId[:]), nil
+}
+
+// int8SliceToString converts a NUL-terminated C-string, as returned by NVML
+// in fixed-size [N]int8 struct fields, into a Go string.
+func int8SliceToString(b []int8) string {
+	buf := make([]byte, 0, len(b))
+	for _, c := range b {
+		if c == 0 {
+			break
+		}
+		buf = append(buf, byte(c))
+	}
+	return string(buf)
+}
+
+// GetBoardPartNumber returns the GPU board's part number.
+func (d *Device) GetBoardPartNumber() (string, error) {
+	partNumber, ret := d.handle.GetBoardPartNumber()
+	if err := nvmlErr(ret); err != nil {
+		return "", err
+	}
+	return partNumber, nil
+}
+
+// GetSerial returns the GPU board's serial number.
+func (d *Device) GetSerial() (string, error) {
+	serial, ret := d.handle.GetSerial()
+	if err := nvmlErr(ret); err != nil {
+		return "", err
+	}
+	return serial, nil
+}
+
+// GetRunningProcesses returns the compute and graphics processes currently
+// running on the GPU, deduplicated by PID: a CUDA+graphics/OpenGL-interop
+// process shows up in both NVML lists, and emitting it twice would give a
+// scrape two identically-labeled samples for the same metric.
+func (d *Device) GetRunningProcesses() ([]NVMLProcess, error) {
+	compute, ret := d.handle.GetComputeRunningProcesses()
+	if err := nvmlErr(ret); err != nil {
+		return nil, err
+	}
+	graphics, ret := d.handle.GetGraphicsRunningProcesses()
+	if err := nvmlErr(ret); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int]bool, len(compute)+len(graphics))
+	processes := make([]NVMLProcess, 0, len(compute)+len(graphics))
+	addProcess := func(pid int, usedGPUMemory uint64) {
+		if seen[pid] {
+			return
+		}
+		seen[pid] = true
+		processes = append(processes, NVMLProcess{PID: pid, UsedGPUMemory: usedGPUMemory})
+	}
+	for _, p := range compute {
+		addProcess(int(p.Pid), p.UsedGpuMemory)
+	}
+	for _, p := range graphics {
+		addProcess(int(p.Pid), p.UsedGpuMemory)
+	}
+	return processes, nil
+}
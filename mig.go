@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+)
+
+var (
+	migEnabled  bool
+	migUseUUID  bool
+	migUseSlice bool
+)
+
+// MIGDevice wraps a single MIG (Multi-Instance GPU) compute instance,
+// carrying the identifiers needed to label its metrics alongside its
+// parent's.
+type MIGDevice struct {
+	parent *Device
+	handle nvml.Device
+
+	UUID  string
+	GIID  int
+	CIID  int
+	Slice string
+}
+
+// DeviceID returns the label value to use for this MIG instance's device_id,
+// honoring --mig.use-uuid.
+func (m MIGDevice) DeviceID() string {
+	if migUseUUID {
+		return m.UUID
+	}
+	return fmt.Sprintf("%d-%d-%d", m.parent.i, m.GIID, m.CIID)
+}
+
+// GetMIGDevices enumerates the MIG compute instances configured on a device.
+// It returns an empty slice, with no error, on GPUs where MIG is unsupported
+// or disabled.
+func (d *Device) GetMIGDevices() ([]MIGDevice, error) {
+	migMode, _, ret := d.handle.GetMigMode()
+	if err := nvmlErr(ret); err != nil {
+		if ret == nvml.ERROR_NOT_SUPPORTED {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if migMode != nvml.DEVICE_MIG_ENABLE {
+		return nil, nil
+	}
+
+	maxGI, ret := d.handle.GetMaxMigDeviceCount()
+	if err := nvmlErr(ret); err != nil {
+		return nil, err
+	}
+
+	devices := make([]MIGDevice, 0, maxGI)
+	for i := 0; i < maxGI; i++ {
+		migHandle, ret := d.handle.GetMigDeviceHandleByIndex(i)
+		if ret == nvml.ERROR_NOT_FOUND || ret == nvml.ERROR_INVALID_ARGUMENT {
+			continue
+		}
+		if err := nvmlErr(ret); err != nil {
+			return nil, err
+		}
+
+		uuid, ret := migHandle.GetUUID()
+		if err := nvmlErr(ret); err != nil {
+			return nil, err
+		}
+
+		giID, ret := migHandle.GetGpuInstanceId()
+		if err := nvmlErr(ret); err != nil {
+			return nil, err
+		}
+
+		ciID, ret := migHandle.GetComputeInstanceId()
+		if err := nvmlErr(ret); err != nil {
+			return nil, err
+		}
+
+		slice := ""
+		if migUseSlice {
+			if gi, ret := d.handle.GetGpuInstanceById(giID); ret == nvml.SUCCESS {
+				if giInfo, ret := gi.GetInfo(); ret == nvml.SUCCESS {
+					if profile, ret := d.handle.GetGpuInstanceProfileInfo(int(giInfo.ProfileId)); ret == nvml.SUCCESS {
+						slice = fmt.Sprintf("%d", profile.SliceCount)
+					}
+				}
+			}
+		}
+
+		devices = append(devices, MIGDevice{
+			parent: d,
+			handle: migHandle,
+			UUID:   uuid,
+			GIID:   giID,
+			CIID:   ciID,
+			Slice:  slice,
+		})
+	}
+
+	return devices, nil
+}
+
+// GetUtilization returns the percent of the MIG instance's own SM and memory
+// bandwidth that are utilized.
+func (m MIGDevice) GetUtilization() (smPercent, memoryPercent int, err error) {
+	util, ret := m.handle.GetUtilizationRates()
+	if err = nvmlErr(ret); err != nil {
+		return 0, 0, err
+	}
+	return int(util.Gpu), int(util.Memory), nil
+}
+
+// GetMemoryInfo returns the MIG instance's own total, used and free memory in bytes.
+func (m MIGDevice) GetMemoryInfo() (NVMLMemory, error) {
+	mem, ret := m.handle.GetMemoryInfo()
+	if err := nvmlErr(ret); err != nil {
+		return NVMLMemory{}, err
+	}
+	return NVMLMemory{
+		Total: mem.Total,
+		Used:  mem.Used,
+		Free:  mem.Free,
+	}, nil
+}
+
+// NVLinkState describes a single NVLink's traffic and error counters.
+type NVLinkState struct {
+	Link           int
+	TxBytes        uint64
+	RxBytes        uint64
+	CRCErrors      uint64
+	ReplayErrors   uint64
+	RecoveryErrors uint64
+}
+
+// GetNVLinks returns the state of every active NVLink on the device, skipping
+// links that are down or not present.
+func (d *Device) GetNVLinks() ([]NVLinkState, error) {
+	links := make([]NVLinkState, 0, nvml.NVLINK_MAX_LINKS)
+
+	for link := 0; link < nvml.NVLINK_MAX_LINKS; link++ {
+		state, ret := d.handle.GetNvLinkState(link)
+		if ret == nvml.ERROR_NOT_SUPPORTED || ret == nvml.ERROR_INVALID_ARGUMENT {
+			continue
+		}
+		if err := nvmlErr(ret); err != nil {
+			return nil, err
+		}
+		if state != nvml.FEATURE_ENABLED {
+			continue
+		}
+
+		rx, tx, ret := d.handle.GetNvLinkUtilizationCounter(link, 0)
+		if err := nvmlErr(ret); err != nil {
+			return nil, err
+		}
+		crc, ret := d.handle.GetNvLinkErrorCounter(link, nvml.NVLINK_ERROR_DL_CRC_DATA)
+		if err := nvmlErr(ret); err != nil {
+			return nil, err
+		}
+		replay, ret := d.handle.GetNvLinkErrorCounter(link, nvml.NVLINK_ERROR_DL_REPLAY)
+		if err := nvmlErr(ret); err != nil {
+			return nil, err
+		}
+		recovery, ret := d.handle.GetNvLinkErrorCounter(link, nvml.NVLINK_ERROR_DL_RECOVERY)
+		if err := nvmlErr(ret); err != nil {
+			return nil, err
+		}
+
+		links = append(links, NVLinkState{
+			Link:           link,
+			TxBytes:        tx,
+			RxBytes:        rx,
+			CRCErrors:      crc,
+			ReplayErrors:   replay,
+			RecoveryErrors: recovery,
+		})
+	}
+
+	return links, nil
+}
@@ -5,26 +5,40 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"sync"
 	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-// VecInfo stores the prometheus help and labels to
+// VecInfo stores the prometheus help, labels and type for a metric.
+// counter marks metrics backed by a monotonically increasing NVML counter;
+// everything else is exported as a gauge.
 type VecInfo struct {
-	help   string
-	labels []string
+	help    string
+	labels  []string
+	counter bool
+}
+
+// valueType returns the prometheus.ValueType to use when emitting this metric.
+func (v *VecInfo) valueType() prometheus.ValueType {
+	if v.counter {
+		return prometheus.CounterValue
+	}
+	return prometheus.GaugeValue
 }
 
 var (
 	// DefaultNamespace is the base namespace used by the exporter
 	DefaultNamespace = "nvml"
 	// unexported variables below
-	listenAddress string
-	metricsPath   string
+	listenAddress   string
+	metricsPath     string
+	disabledMetrics string
 
-	gaugeMetrics = map[string]*VecInfo{
+	metricInfo = map[string]*VecInfo{
 		"power_watts": &VecInfo{
 			help:   "Power Usage of an NVIDIA GPU in Watts",
 			labels: []string{"device_id", "device_uuid", "device_name"},
@@ -57,42 +71,268 @@ var (
 			help:   "GPU Temperature in Celsius",
 			labels: []string{"device_id", "device_uuid", "device_name"},
 		},
+		"temperature": &VecInfo{
+			help:   "GPU Temperature in degrees, standardized on the unit configured via unit_prefix[\"temperature\"] (K/F/C, defaults to Celsius)",
+			labels: []string{"device_id", "device_uuid", "device_name"},
+		},
+		"clock_sm_mhz": &VecInfo{
+			help:   "SM clock frequency in MHz",
+			labels: []string{"device_id", "device_uuid", "device_name"},
+		},
+		"clock_memory_mhz": &VecInfo{
+			help:   "Memory clock frequency in MHz",
+			labels: []string{"device_id", "device_uuid", "device_name"},
+		},
+		"clock_graphics_mhz": &VecInfo{
+			help:   "Graphics clock frequency in MHz",
+			labels: []string{"device_id", "device_uuid", "device_name"},
+		},
+		"clock_video_mhz": &VecInfo{
+			help:   "Video clock frequency in MHz",
+			labels: []string{"device_id", "device_uuid", "device_name"},
+		},
+		"clock_throttle_reasons": &VecInfo{
+			help:   "Bitmask of active clock throttle reasons",
+			labels: []string{"device_id", "device_uuid", "device_name"},
+		},
+		"ecc_errors_volatile_single_bit": &VecInfo{
+			help:    "Volatile single bit ECC error count",
+			labels:  []string{"device_id", "device_uuid", "device_name"},
+			counter: true,
+		},
+		"ecc_errors_volatile_double_bit": &VecInfo{
+			help:    "Volatile double bit ECC error count",
+			labels:  []string{"device_id", "device_uuid", "device_name"},
+			counter: true,
+		},
+		"ecc_errors_aggregate_single_bit": &VecInfo{
+			help:    "Aggregate single bit ECC error count",
+			labels:  []string{"device_id", "device_uuid", "device_name"},
+			counter: true,
+		},
+		"ecc_errors_aggregate_double_bit": &VecInfo{
+			help:    "Aggregate double bit ECC error count",
+			labels:  []string{"device_id", "device_uuid", "device_name"},
+			counter: true,
+		},
+		"pcie_tx_bytes": &VecInfo{
+			help:   "PCIe transmit throughput in bytes per second",
+			labels: []string{"device_id", "device_uuid", "device_name"},
+		},
+		"pcie_rx_bytes": &VecInfo{
+			help:   "PCIe receive throughput in bytes per second",
+			labels: []string{"device_id", "device_uuid", "device_name"},
+		},
+		"pcie_replay_errors": &VecInfo{
+			help:    "Total PCIe replay error count",
+			labels:  []string{"device_id", "device_uuid", "device_name"},
+			counter: true,
+		},
+		"pcie_link_generation": &VecInfo{
+			help:   "Current PCIe link generation",
+			labels: []string{"device_id", "device_uuid", "device_name"},
+		},
+		"pcie_link_width": &VecInfo{
+			help:   "Current PCIe link width",
+			labels: []string{"device_id", "device_uuid", "device_name"},
+		},
+		"fan_speed_percent": &VecInfo{
+			help:   "Fan speed as a percent of the maximum",
+			labels: []string{"device_id", "device_uuid", "device_name"},
+		},
+		"performance_state": &VecInfo{
+			help:   "Current performance state (P-state), 0 being the highest",
+			labels: []string{"device_id", "device_uuid", "device_name"},
+		},
+		"power_limit_watts": &VecInfo{
+			help:   "Configured power management limit in Watts",
+			labels: []string{"device_id", "device_uuid", "device_name"},
+		},
+		"power_limit_default_watts": &VecInfo{
+			help:   "Default power management limit in Watts",
+			labels: []string{"device_id", "device_uuid", "device_name"},
+		},
+		"encoder_percent": &VecInfo{
+			help:   "Percent of the video encoder utilized",
+			labels: []string{"device_id", "device_uuid", "device_name"},
+		},
+		"decoder_percent": &VecInfo{
+			help:   "Percent of the video decoder utilized",
+			labels: []string{"device_id", "device_uuid", "device_name"},
+		},
+		"process_memory_used_bytes": &VecInfo{
+			help:   "Bytes of GPU memory used by a process",
+			labels: []string{"device_id", "device_uuid", "device_name", "pid"},
+		},
+		"mig_sm_percent": &VecInfo{
+			help:   "Percent of a MIG instance's SM utilized",
+			labels: []string{"device_id", "device_uuid", "device_name", "mig_uuid", "mig_gi_id", "mig_ci_id", "mig_slice"},
+		},
+		"mig_memory_percent": &VecInfo{
+			help:   "Percent of a MIG instance's memory utilized",
+			labels: []string{"device_id", "device_uuid", "device_name", "mig_uuid", "mig_gi_id", "mig_ci_id", "mig_slice"},
+		},
+		"mig_memory_used_bytes": &VecInfo{
+			help:   "Bytes of memory used by a MIG instance",
+			labels: []string{"device_id", "device_uuid", "device_name", "mig_uuid", "mig_gi_id", "mig_ci_id", "mig_slice"},
+		},
+		"mig_memory_total_bytes": &VecInfo{
+			help:   "Total bytes of memory available to a MIG instance",
+			labels: []string{"device_id", "device_uuid", "device_name", "mig_uuid", "mig_gi_id", "mig_ci_id", "mig_slice"},
+		},
+		"mig_memory_free_bytes": &VecInfo{
+			help:   "Bytes of memory free on a MIG instance",
+			labels: []string{"device_id", "device_uuid", "device_name", "mig_uuid", "mig_gi_id", "mig_ci_id", "mig_slice"},
+		},
+		"nvlink_tx_bytes": &VecInfo{
+			help:    "NVLink transmit byte counter",
+			labels:  []string{"device_id", "device_uuid", "device_name", "link"},
+			counter: true,
+		},
+		"nvlink_rx_bytes": &VecInfo{
+			help:    "NVLink receive byte counter",
+			labels:  []string{"device_id", "device_uuid", "device_name", "link"},
+			counter: true,
+		},
+		"nvlink_crc_errors": &VecInfo{
+			help:    "NVLink data CRC error counter",
+			labels:  []string{"device_id", "device_uuid", "device_name", "link"},
+			counter: true,
+		},
+		"nvlink_replay_errors": &VecInfo{
+			help:    "NVLink replay error counter",
+			labels:  []string{"device_id", "device_uuid", "device_name", "link"},
+			counter: true,
+		},
+		"nvlink_recovery_errors": &VecInfo{
+			help:    "NVLink recovery error counter",
+			labels:  []string{"device_id", "device_uuid", "device_name", "link"},
+			counter: true,
+		},
+		"energy_joules_total": &VecInfo{
+			help:    "Total energy consumed by the GPU since the driver was loaded, in Joules",
+			labels:  []string{"device_id", "device_uuid", "device_name"},
+			counter: true,
+		},
+		"retired_pages_total": &VecInfo{
+			help:    "Number of memory pages retired due to ECC errors",
+			labels:  []string{"device_id", "device_uuid", "device_name", "cause"},
+			counter: true,
+		},
 	}
 )
 
+// disabledMetricSet returns the set of metric names disabled via --collector.disable.
+func disabledMetricSet() map[string]bool {
+	disabled := make(map[string]bool)
+	for _, name := range strings.Split(disabledMetrics, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			disabled[name] = true
+		}
+	}
+	return disabled
+}
+
 // Exporter TODO
 type Exporter struct {
 	mutex sync.RWMutex
 
-	up     prometheus.Gauge
-	gauges map[string]*prometheus.GaugeVec
+	up    prometheus.Gauge
+	descs map[string]*prometheus.Desc
+
+	config             *Config
+	memoryUnit         string
+	memoryDivisor      float64
+	temperatureUnit    string
+	temperatureConvert func(celsius int) float64
+	deviceInfo         *prometheus.Desc
 
 	devices []Device
 }
 
 // NewExporter TODO
 func NewExporter() (exp *Exporter, err error) {
+	config, err := loadConfig(configFile)
+	if err != nil {
+		return nil, err
+	}
+
 	exp = &Exporter{
-		gauges: make(map[string]*prometheus.GaugeVec, len(gaugeMetrics)),
+		descs:  make(map[string]*prometheus.Desc, len(metricInfo)),
+		config: config,
 		up: prometheus.NewGauge(prometheus.GaugeOpts{
 			Namespace: DefaultNamespace,
 			Name:      "up",
 			Help:      "Were the NVML queries successful?",
 		}),
 	}
+	exp.memoryUnit, exp.memoryDivisor = config.memoryUnit()
+	exp.temperatureUnit, exp.temperatureConvert = config.temperatureUnit()
 
-	if exp.devices, err = GetDevices(); err != nil {
-		return
+	devices, err := GetDevices()
+	if err != nil {
+		return nil, err
+	}
+	for _, device := range devices {
+		if !config.excludesDevice(device.DeviceUUID) {
+			exp.devices = append(exp.devices, device)
+		}
 	}
 
-	for name, info := range gaugeMetrics {
-		exp.gauges[name] = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Namespace: DefaultNamespace,
-			Name:      name,
-			Help:      info.help,
-		}, info.labels)
+	disabled := disabledMetricSet()
+	for name, info := range metricInfo {
+		if disabled[name] || config.excludesMetric(name) {
+			continue
+		}
+		help := info.help
+		switch name {
+		case "memory_free", "memory_total", "memory_used":
+			help = strings.Replace(help, "bytes", exp.memoryUnit, 1)
+		case "temperature":
+			help = strings.Replace(help, "degrees", "degrees "+exp.temperatureUnit, 1)
+		}
+		exp.descs[name] = prometheus.NewDesc(
+			prometheus.BuildFQName(DefaultNamespace, "", name),
+			help,
+			info.labels,
+			nil,
+		)
 	}
-	return
+
+	if config.AddPCIInfoTag || config.AddUUIDMeta || config.AddBoardNumberMeta || config.AddSerialMeta {
+		labels := []string{"device_id", "device_uuid", "device_name"}
+		if config.AddPCIInfoTag {
+			labels = append(labels, "pci_bus_id")
+		}
+		if config.AddUUIDMeta {
+			labels = append(labels, "uuid")
+		}
+		if config.AddBoardNumberMeta {
+			labels = append(labels, "board_part_number")
+		}
+		if config.AddSerialMeta {
+			labels = append(labels, "serial")
+		}
+		exp.deviceInfo = prometheus.NewDesc(
+			prometheus.BuildFQName(DefaultNamespace, "", "device_info"),
+			"Static metadata about a GPU, always 1",
+			labels,
+			nil,
+		)
+	}
+
+	return exp, nil
+}
+
+// emit sends a single sample for name to ch, silently doing nothing if that
+// metric was disabled via --collector.disable.
+func (e *Exporter) emit(ch chan<- prometheus.Metric, name string, value float64, labelValues ...string) {
+	desc, ok := e.descs[name]
+	if !ok {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(desc, metricInfo[name].valueType(), value, labelValues...)
 }
 
 // Describe describes all the metrics ever exported by the nvml/nvidia exporter.
@@ -100,13 +340,17 @@ func NewExporter() (exp *Exporter, err error) {
 func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
 	ch <- e.up.Desc()
 
-	for _, vec := range e.gauges {
-		vec.Describe(ch)
+	for _, desc := range e.descs {
+		ch <- desc
+	}
+	if e.deviceInfo != nil {
+		ch <- e.deviceInfo
 	}
 }
 
 // GetTelemetryFromNVML collects device telemetry from all NVIDIA GPUs connected to this machine
-func (e *Exporter) GetTelemetryFromNVML() {
+// and emits it directly to ch, as prometheus.Collector.Collect expects.
+func (e *Exporter) GetTelemetryFromNVML(ch chan<- prometheus.Metric) {
 	var (
 		gpuMem                    NVMLMemory
 		powerUsage                int
@@ -116,37 +360,258 @@ func (e *Exporter) GetTelemetryFromNVML() {
 	)
 
 	for _, device := range e.devices {
+		id, uuid, name := strconv.Itoa(device.i), device.DeviceUUID, device.DeviceName
+
 		if gpuPercent, memoryPercent, err = device.GetUtilization(); err != nil {
 			fmt.Printf("Failed to get Device Utilization for %s: %s\n", device.DeviceUUID, err.Error())
 			e.up.Set(0)
 			return
 		}
-		e.gauges["gpu_percent"].WithLabelValues(strconv.Itoa(device.i), device.DeviceUUID, device.DeviceName).Set(float64(gpuPercent))
-		e.gauges["memory_percent"].WithLabelValues(strconv.Itoa(device.i), device.DeviceUUID, device.DeviceName).Set(float64(memoryPercent))
+		e.emit(ch, "gpu_percent", float64(gpuPercent), id, uuid, name)
+		e.emit(ch, "memory_percent", float64(memoryPercent), id, uuid, name)
 
 		if tempF, tempC, err = device.GetTemperature(); err != nil {
 			fmt.Printf("Failed to get Device Temperature for %s: %s\n", device.DeviceUUID, err.Error())
 			e.up.Set(0)
 			return
 		}
-		e.gauges["temperature_celsius"].WithLabelValues(strconv.Itoa(device.i), device.DeviceUUID, device.DeviceName).Set(float64(tempC))
-		e.gauges["temperature_fahrenheit"].WithLabelValues(strconv.Itoa(device.i), device.DeviceUUID, device.DeviceName).Set(float64(tempF))
+		e.emit(ch, "temperature_celsius", float64(tempC), id, uuid, name)
+		e.emit(ch, "temperature_fahrenheit", float64(tempF), id, uuid, name)
+		e.emit(ch, "temperature", e.temperatureConvert(tempC), id, uuid, name)
 
 		if powerUsage, err = device.GetPowerUsage(); err != nil {
 			fmt.Printf("Failed to get Device Power Usage for %s: %s\n", device.DeviceUUID, err.Error())
 			e.up.Set(0)
 			return
 		}
-		e.gauges["power_watts"].WithLabelValues(strconv.Itoa(device.i), device.DeviceUUID, device.DeviceName).Set(float64(powerUsage))
+		e.emit(ch, "power_watts", float64(powerUsage), id, uuid, name)
 
 		if gpuMem, err = device.GetMemoryInfo(); err != nil {
 			fmt.Printf("Failed to get Memory Info for %s: %s\n", device.DeviceUUID, err.Error())
 			e.up.Set(0)
 			return
 		}
-		e.gauges["memory_free"].WithLabelValues(strconv.Itoa(device.i), device.DeviceUUID, device.DeviceName).Set(float64(gpuMem.Free))
-		e.gauges["memory_total"].WithLabelValues(strconv.Itoa(device.i), device.DeviceUUID, device.DeviceName).Set(float64(gpuMem.Total))
-		e.gauges["memory_used"].WithLabelValues(strconv.Itoa(device.i), device.DeviceUUID, device.DeviceName).Set(float64(gpuMem.Used))
+		e.emit(ch, "memory_free", float64(gpuMem.Free)/e.memoryDivisor, id, uuid, name)
+		e.emit(ch, "memory_total", float64(gpuMem.Total)/e.memoryDivisor, id, uuid, name)
+		e.emit(ch, "memory_used", float64(gpuMem.Used)/e.memoryDivisor, id, uuid, name)
+
+		if e.deviceInfo != nil {
+			labelValues := []string{id, uuid, name}
+			if e.config.AddPCIInfoTag {
+				pciBusID, err := device.GetPciBusID()
+				if err != nil {
+					fmt.Printf("Failed to get PCI Bus ID for %s: %s\n", device.DeviceUUID, err.Error())
+					e.up.Set(0)
+					return
+				}
+				labelValues = append(labelValues, pciBusID)
+			}
+			if e.config.AddUUIDMeta {
+				labelValues = append(labelValues, uuid)
+			}
+			if e.config.AddBoardNumberMeta {
+				boardNumber, err := device.GetBoardPartNumber()
+				if err != nil {
+					fmt.Printf("Failed to get Board Part Number for %s: %s\n", device.DeviceUUID, err.Error())
+					e.up.Set(0)
+					return
+				}
+				labelValues = append(labelValues, boardNumber)
+			}
+			if e.config.AddSerialMeta {
+				serial, err := device.GetSerial()
+				if err != nil {
+					fmt.Printf("Failed to get Serial for %s: %s\n", device.DeviceUUID, err.Error())
+					e.up.Set(0)
+					return
+				}
+				labelValues = append(labelValues, serial)
+			}
+			ch <- prometheus.MustNewConstMetric(e.deviceInfo, prometheus.GaugeValue, 1, labelValues...)
+		}
+
+		if clocks, err := device.GetClocks(); err != nil {
+			if !isNotSupported(err) {
+				fmt.Printf("Failed to get Clocks for %s: %s\n", device.DeviceUUID, err.Error())
+				e.up.Set(0)
+				return
+			}
+		} else {
+			e.emit(ch, "clock_sm_mhz", float64(clocks.SM), id, uuid, name)
+			e.emit(ch, "clock_memory_mhz", float64(clocks.Memory), id, uuid, name)
+			e.emit(ch, "clock_graphics_mhz", float64(clocks.Graphics), id, uuid, name)
+			e.emit(ch, "clock_video_mhz", float64(clocks.Video), id, uuid, name)
+		}
+
+		if throttleReasons, err := device.GetClockThrottleReasons(); err != nil {
+			if !isNotSupported(err) {
+				fmt.Printf("Failed to get Clock Throttle Reasons for %s: %s\n", device.DeviceUUID, err.Error())
+				e.up.Set(0)
+				return
+			}
+		} else {
+			e.emit(ch, "clock_throttle_reasons", float64(throttleReasons), id, uuid, name)
+		}
+
+		if ecc, err := device.GetEccErrors(); err != nil {
+			if !isNotSupported(err) {
+				fmt.Printf("Failed to get ECC Errors for %s: %s\n", device.DeviceUUID, err.Error())
+				e.up.Set(0)
+				return
+			}
+		} else {
+			e.emit(ch, "ecc_errors_volatile_single_bit", float64(ecc.VolatileSingleBit), id, uuid, name)
+			e.emit(ch, "ecc_errors_volatile_double_bit", float64(ecc.VolatileDoubleBit), id, uuid, name)
+			e.emit(ch, "ecc_errors_aggregate_single_bit", float64(ecc.AggregateSingleBit), id, uuid, name)
+			e.emit(ch, "ecc_errors_aggregate_double_bit", float64(ecc.AggregateDoubleBit), id, uuid, name)
+		}
+
+		if pcie, err := device.GetPcieInfo(); err != nil {
+			if !isNotSupported(err) {
+				fmt.Printf("Failed to get PCIe Info for %s: %s\n", device.DeviceUUID, err.Error())
+				e.up.Set(0)
+				return
+			}
+		} else {
+			e.emit(ch, "pcie_tx_bytes", float64(pcie.TxBytes), id, uuid, name)
+			e.emit(ch, "pcie_rx_bytes", float64(pcie.RxBytes), id, uuid, name)
+			e.emit(ch, "pcie_link_generation", float64(pcie.LinkGen), id, uuid, name)
+			e.emit(ch, "pcie_link_width", float64(pcie.LinkWidth), id, uuid, name)
+		}
+
+		if fanSpeed, err := device.GetFanSpeed(); err != nil {
+			if !isNotSupported(err) {
+				fmt.Printf("Failed to get Fan Speed for %s: %s\n", device.DeviceUUID, err.Error())
+				e.up.Set(0)
+				return
+			}
+		} else {
+			e.emit(ch, "fan_speed_percent", float64(fanSpeed), id, uuid, name)
+		}
+
+		if pstate, err := device.GetPerformanceState(); err != nil {
+			if !isNotSupported(err) {
+				fmt.Printf("Failed to get Performance State for %s: %s\n", device.DeviceUUID, err.Error())
+				e.up.Set(0)
+				return
+			}
+		} else {
+			e.emit(ch, "performance_state", float64(pstate), id, uuid, name)
+		}
+
+		if powerLimits, err := device.GetPowerLimits(); err != nil {
+			if !isNotSupported(err) {
+				fmt.Printf("Failed to get Power Limits for %s: %s\n", device.DeviceUUID, err.Error())
+				e.up.Set(0)
+				return
+			}
+		} else {
+			e.emit(ch, "power_limit_watts", float64(powerLimits.Limit), id, uuid, name)
+			e.emit(ch, "power_limit_default_watts", float64(powerLimits.DefaultLimit), id, uuid, name)
+		}
+
+		if encoderPercent, err := device.GetEncoderUtilization(); err != nil {
+			if !isNotSupported(err) {
+				fmt.Printf("Failed to get Encoder Utilization for %s: %s\n", device.DeviceUUID, err.Error())
+				e.up.Set(0)
+				return
+			}
+		} else {
+			e.emit(ch, "encoder_percent", float64(encoderPercent), id, uuid, name)
+		}
+
+		if decoderPercent, err := device.GetDecoderUtilization(); err != nil {
+			if !isNotSupported(err) {
+				fmt.Printf("Failed to get Decoder Utilization for %s: %s\n", device.DeviceUUID, err.Error())
+				e.up.Set(0)
+				return
+			}
+		} else {
+			e.emit(ch, "decoder_percent", float64(decoderPercent), id, uuid, name)
+		}
+
+		if processes, err := device.GetRunningProcesses(); err != nil {
+			if !isNotSupported(err) {
+				fmt.Printf("Failed to get Running Processes for %s: %s\n", device.DeviceUUID, err.Error())
+				e.up.Set(0)
+				return
+			}
+		} else {
+			for _, process := range processes {
+				e.emit(ch, "process_memory_used_bytes", float64(process.UsedGPUMemory), id, uuid, name, strconv.Itoa(process.PID))
+			}
+		}
+
+		if migEnabled {
+			migDevices, err := device.GetMIGDevices()
+			if err != nil {
+				fmt.Printf("Failed to get MIG Devices for %s: %s\n", device.DeviceUUID, err.Error())
+				e.up.Set(0)
+				return
+			}
+			for _, mig := range migDevices {
+				migID, migGI, migCI := mig.DeviceID(), strconv.Itoa(mig.GIID), strconv.Itoa(mig.CIID)
+
+				smPercent, memPercent, err := mig.GetUtilization()
+				if err != nil {
+					fmt.Printf("Failed to get MIG Utilization for %s: %s\n", mig.UUID, err.Error())
+					e.up.Set(0)
+					return
+				}
+				e.emit(ch, "mig_sm_percent", float64(smPercent), migID, uuid, name, mig.UUID, migGI, migCI, mig.Slice)
+				e.emit(ch, "mig_memory_percent", float64(memPercent), migID, uuid, name, mig.UUID, migGI, migCI, mig.Slice)
+
+				migMem, err := mig.GetMemoryInfo()
+				if err != nil {
+					fmt.Printf("Failed to get MIG Memory Info for %s: %s\n", mig.UUID, err.Error())
+					e.up.Set(0)
+					return
+				}
+				e.emit(ch, "mig_memory_used_bytes", float64(migMem.Used), migID, uuid, name, mig.UUID, migGI, migCI, mig.Slice)
+				e.emit(ch, "mig_memory_total_bytes", float64(migMem.Total), migID, uuid, name, mig.UUID, migGI, migCI, mig.Slice)
+				e.emit(ch, "mig_memory_free_bytes", float64(migMem.Free), migID, uuid, name, mig.UUID, migGI, migCI, mig.Slice)
+			}
+		}
+
+		nvlinks, err := device.GetNVLinks()
+		if err != nil {
+			fmt.Printf("Failed to get NVLink State for %s: %s\n", device.DeviceUUID, err.Error())
+			e.up.Set(0)
+			return
+		}
+		for _, nvlink := range nvlinks {
+			link := strconv.Itoa(nvlink.Link)
+			e.emit(ch, "nvlink_tx_bytes", float64(nvlink.TxBytes), id, uuid, name, link)
+			e.emit(ch, "nvlink_rx_bytes", float64(nvlink.RxBytes), id, uuid, name, link)
+			e.emit(ch, "nvlink_crc_errors", float64(nvlink.CRCErrors), id, uuid, name, link)
+			e.emit(ch, "nvlink_replay_errors", float64(nvlink.ReplayErrors), id, uuid, name, link)
+			e.emit(ch, "nvlink_recovery_errors", float64(nvlink.RecoveryErrors), id, uuid, name, link)
+		}
+
+		pcieReplayErrors, err := device.GetPcieReplayCounter()
+		if err != nil {
+			fmt.Printf("Failed to get PCIe Replay Counter for %s: %s\n", device.DeviceUUID, err.Error())
+			e.up.Set(0)
+			return
+		}
+		e.emit(ch, "pcie_replay_errors", float64(pcieReplayErrors), id, uuid, name)
+
+		energyJoules, err := device.GetTotalEnergyConsumption()
+		if err != nil {
+			fmt.Printf("Failed to get Total Energy Consumption for %s: %s\n", device.DeviceUUID, err.Error())
+			e.up.Set(0)
+			return
+		}
+		e.emit(ch, "energy_joules_total", float64(energyJoules), id, uuid, name)
+
+		retiredSingleBit, retiredDoubleBit, err := device.GetRetiredPagesCounts()
+		if err != nil {
+			fmt.Printf("Failed to get Retired Pages for %s: %s\n", device.DeviceUUID, err.Error())
+			e.up.Set(0)
+			return
+		}
+		e.emit(ch, "retired_pages_total", float64(retiredSingleBit), id, uuid, name, "single_bit_ecc")
+		e.emit(ch, "retired_pages_total", float64(retiredDoubleBit), id, uuid, name, "double_bit_ecc")
 	}
 }
 
@@ -156,25 +621,22 @@ func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
 
-	for _, vec := range e.gauges {
-		vec.Reset()
-	}
-
 	defer func() { ch <- e.up }()
 
 	// If we fail at any point in retrieving GPU status, we fail 0
 	e.up.Set(1)
 
-	e.GetTelemetryFromNVML()
-
-	for _, vec := range e.gauges {
-		vec.Collect(ch)
-	}
+	e.GetTelemetryFromNVML(ch)
 }
 
 func init() {
 	flag.StringVar(&listenAddress, "web.listen-address", ":9114", "Address to listen on")
 	flag.StringVar(&metricsPath, "web.telemetry-path", "/metrics", "Path under which to expose metrics.")
+	flag.StringVar(&disabledMetrics, "collector.disable", "", "Comma-separated list of metric names to disable, e.g. 'ecc_errors_volatile_single_bit,process_memory_used_bytes'.")
+	flag.BoolVar(&migEnabled, "mig.enabled", false, "Collect per-instance metrics for GPUs running in MIG mode.")
+	flag.BoolVar(&migUseUUID, "mig.use-uuid", false, "Use a MIG instance's UUID as its device_id label instead of <gpu index>-<gi id>-<ci id>.")
+	flag.BoolVar(&migUseSlice, "mig.use-slice", false, "Include the MIG instance's compute-slice profile as the mig_slice label.")
+	flag.StringVar(&configFile, "config.file", "", "Path to a YAML or JSON collector config (exclude_metrics, exclude_devices, add_*_meta, unit_prefix). Unset disables all of it.")
 }
 
 func main() {
@@ -201,7 +663,29 @@ func main() {
 	}
 	prometheus.MustRegister(exporter)
 
-	http.Handle(metricsPath, prometheus.Handler())
+	probeConfig, err := loadConfig(configFile)
+	if err != nil {
+		log.Fatalf("Failed loading config for /probe: %s\n", err.Error())
+	}
+
+	http.Handle(metricsPath, promhttp.Handler())
+	http.HandleFunc("/probe", func(w http.ResponseWriter, r *http.Request) {
+		target := r.URL.Query().Get("target")
+		if target == "" {
+			http.Error(w, "target parameter is required", http.StatusBadRequest)
+			return
+		}
+
+		source, ok := NewRemoteSmiSource(target, probeConfig)
+		if !ok {
+			http.Error(w, "target is not a configured target", http.StatusBadRequest)
+			return
+		}
+
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(source)
+		promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+	})
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write(landingPageHTML)
 	})
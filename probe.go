@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Source is implemented by anything able to supply GPU telemetry for a
+// Prometheus scrape, whether gathered locally via NVML or remotely via
+// nvidia-smi. Exporter (the local NVML collector) and RemoteSmiSource both
+// satisfy it.
+type Source interface {
+	prometheus.Collector
+}
+
+var _ Source = (*Exporter)(nil)
+var _ Source = (*RemoteSmiSource)(nil)
+
+// RemoteTarget describes how to reach a host that cannot run this exporter
+// itself and must instead be probed over SSH with nvidia-smi.
+type RemoteTarget struct {
+	SSHUser    string `yaml:"ssh_user" json:"ssh_user"`
+	SSHKeyFile string `yaml:"ssh_key_file" json:"ssh_key_file"`
+}
+
+// nvidiaSMIQuery lists the nvidia-smi --query-gpu columns RemoteSmiSource
+// requests, in order, and the local metric name each maps to.
+var nvidiaSMIQuery = []struct {
+	column string
+	metric string
+}{
+	{"index", ""},
+	{"uuid", ""},
+	{"name", ""},
+	{"utilization.gpu", "gpu_percent"},
+	{"memory.used", "memory_used"},
+	{"memory.total", "memory_total"},
+	{"memory.free", "memory_free"},
+	{"temperature.gpu", "temperature_celsius"},
+	{"power.draw", "power_watts"},
+}
+
+var remoteSmiDescs = buildRemoteSmiDescs()
+
+func buildRemoteSmiDescs() map[string]*prometheus.Desc {
+	labels := []string{"device_id", "device_uuid", "device_name"}
+	descs := map[string]*prometheus.Desc{
+		"up": prometheus.NewDesc(prometheus.BuildFQName(DefaultNamespace, "", "up"), "Was the probe of this target successful?", nil, nil),
+	}
+	for _, col := range nvidiaSMIQuery {
+		if col.metric == "" {
+			continue
+		}
+		info := metricInfo[col.metric]
+		descs[col.metric] = prometheus.NewDesc(prometheus.BuildFQName(DefaultNamespace, "", col.metric), info.help, labels, nil)
+	}
+	return descs
+}
+
+// RemoteSmiSource probes a single host that cannot run this exporter itself,
+// by running `nvidia-smi --query-gpu=...` over SSH and parsing its CSV
+// output into the same metric names the local NVML source reports.
+type RemoteSmiSource struct {
+	target RemoteTarget
+	host   string
+}
+
+// NewRemoteSmiSource builds a RemoteSmiSource for host, looking up its
+// credentials in cfg.Targets. ok is false, and the source must not be used,
+// if host is not a configured target: cfg.Targets is the allowlist of hosts
+// this exporter is permitted to shell out to, not just a credential lookup.
+func NewRemoteSmiSource(host string, cfg *Config) (source *RemoteSmiSource, ok bool) {
+	target, ok := cfg.Targets[host]
+	if !ok {
+		return nil, false
+	}
+	return &RemoteSmiSource{host: host, target: target}, true
+}
+
+// Describe implements prometheus.Collector.
+func (s *RemoteSmiSource) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range remoteSmiDescs {
+		ch <- desc
+	}
+}
+
+// Collect implements prometheus.Collector. It runs nvidia-smi on the remote
+// host over SSH and reports `up` as 0, with no other metrics, on failure.
+func (s *RemoteSmiSource) Collect(ch chan<- prometheus.Metric) {
+	rows, err := s.queryNvidiaSMI()
+	if err != nil {
+		fmt.Printf("Failed to probe %s: %s\n", s.host, err.Error())
+		ch <- prometheus.MustNewConstMetric(remoteSmiDescs["up"], prometheus.GaugeValue, 0)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(remoteSmiDescs["up"], prometheus.GaugeValue, 1)
+
+	for _, row := range rows {
+		id, uuid, name := row[0], row[1], row[2]
+		for i, col := range nvidiaSMIQuery {
+			if col.metric == "" {
+				continue
+			}
+			value, err := strconv.ParseFloat(strings.TrimSpace(row[i]), 64)
+			if err != nil {
+				fmt.Printf("Failed to parse %s for %s on %s: %s\n", col.column, uuid, s.host, err.Error())
+				continue
+			}
+			// nvidia-smi reports memory in MiB; normalize to bytes to match the local collector.
+			if col.metric == "memory_used" || col.metric == "memory_total" || col.metric == "memory_free" {
+				value *= 1024 * 1024
+			}
+			ch <- prometheus.MustNewConstMetric(remoteSmiDescs[col.metric], prometheus.GaugeValue, value, id, uuid, name)
+		}
+	}
+}
+
+// queryNvidiaSMI runs nvidia-smi on the remote host over SSH and parses its
+// CSV output, one row per GPU.
+func (s *RemoteSmiSource) queryNvidiaSMI() ([][]string, error) {
+	columns := make([]string, len(nvidiaSMIQuery))
+	for i, col := range nvidiaSMIQuery {
+		columns[i] = col.column
+	}
+
+	sshArgs := []string{}
+	if s.target.SSHKeyFile != "" {
+		sshArgs = append(sshArgs, "-i", s.target.SSHKeyFile)
+	}
+	destination := s.host
+	if s.target.SSHUser != "" {
+		destination = s.target.SSHUser + "@" + s.host
+	}
+	// "--" stops ssh from parsing destination (and thus s.host, which cfg.Targets
+	// constrains but which nothing stops from starting with "-") as an option.
+	sshArgs = append(sshArgs, "--", destination, "nvidia-smi", "--query-gpu="+strings.Join(columns, ","), "--format=csv,noheader,nounits")
+
+	out, err := exec.Command("ssh", sshArgs...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("ssh nvidia-smi on %s: %s", s.host, err.Error())
+	}
+
+	reader := csv.NewReader(strings.NewReader(string(out)))
+	reader.TrimLeadingSpace = true
+	return reader.ReadAll()
+}